@@ -0,0 +1,26 @@
+package history
+
+import "os"
+
+// NewStoreFromEnv builds a Store at the path in HISTORY_DB_PATH, defaulting
+// to "history.db" in the working directory.
+func NewStoreFromEnv() (*Store, error) {
+	path := os.Getenv("HISTORY_DB_PATH")
+	if path == "" {
+		path = "history.db"
+	}
+	return NewStore(path)
+}
+
+// NewScrobblerFromEnv builds a LastFMScrobbler from SCROBBLE_LASTFM_KEY,
+// SCROBBLE_LASTFM_SECRET, and SCROBBLE_LASTFM_SESSION. It returns nil if
+// any of the three are unset, so forwarding is opt-in.
+func NewScrobblerFromEnv() *LastFMScrobbler {
+	key := os.Getenv("SCROBBLE_LASTFM_KEY")
+	secret := os.Getenv("SCROBBLE_LASTFM_SECRET")
+	session := os.Getenv("SCROBBLE_LASTFM_SESSION")
+	if key == "" || secret == "" || session == "" {
+		return nil
+	}
+	return NewLastFMScrobbler(key, secret, session)
+}
@@ -0,0 +1,196 @@
+// Package history persists a record of every distinct track the monitor
+// has seen to a local SQLite database, so the monitor can double as a
+// personal scrobbler: serving its own play history over HTTP and, when
+// configured, forwarding scrobbles to Last.fm.
+package history
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// scrobbleMinDurationMs is the minimum track length Last.fm will scrobble;
+// anything shorter never crosses the threshold regardless of how much of
+// it played.
+const scrobbleMinDurationMs = 30 * 1000
+
+// scrobbleCapMs is the length of time a track must play before it
+// qualifies for a scrobble, capped at 4 minutes even for very long tracks.
+const scrobbleCapMs = 4 * 60 * 1000
+
+// Entry is one distinct track play recorded in history.
+type Entry struct {
+	ID         int64
+	Timestamp  time.Time
+	Track      string
+	Album      string
+	Artist     string
+	TrackID    string
+	DurationMs int
+	PlayedMs   int
+	Scrobbled  bool
+}
+
+// Scrobbled reports whether playedMs of a track durationMs long crosses
+// Last.fm's scrobble threshold: played for at least half its duration, or
+// four minutes, whichever is shorter, and the track itself is at least 30
+// seconds long.
+func Scrobbled(durationMs, playedMs int) bool {
+	if durationMs < scrobbleMinDurationMs {
+		return false
+	}
+	threshold := durationMs / 2
+	if threshold > scrobbleCapMs {
+		threshold = scrobbleCapMs
+	}
+	return playedMs >= threshold
+}
+
+// Store is a SQLite-backed listening history.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore opens (creating if necessary) the SQLite database at path and
+// ensures its schema exists.
+func NewStore(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS plays (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			timestamp INTEGER NOT NULL,
+			track TEXT NOT NULL,
+			album TEXT NOT NULL,
+			artist TEXT NOT NULL,
+			track_id TEXT NOT NULL,
+			duration_ms INTEGER NOT NULL,
+			played_ms INTEGER NOT NULL,
+			scrobbled INTEGER NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS plays_timestamp_idx ON plays (timestamp);
+	`); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Record inserts a play and returns its assigned ID.
+func (s *Store) Record(ctx context.Context, e Entry) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO plays (timestamp, track, album, artist, track_id, duration_ms, played_ms, scrobbled)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, e.Timestamp.UnixMilli(), e.Track, e.Album, e.Artist, e.TrackID, e.DurationMs, e.PlayedMs, e.Scrobbled)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// List returns up to limit plays at or after since, most recent first. A
+// limit of 0 returns all matching plays.
+func (s *Store) List(ctx context.Context, limit int, since time.Time) ([]Entry, error) {
+	query := `
+		SELECT id, timestamp, track, album, artist, track_id, duration_ms, played_ms, scrobbled
+		FROM plays
+		WHERE timestamp >= ?
+		ORDER BY timestamp DESC
+	`
+	args := []interface{}{since.UnixMilli()}
+	if limit > 0 {
+		query += `LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		var timestampMs int64
+		if err := rows.Scan(&e.ID, &timestampMs, &e.Track, &e.Album, &e.Artist, &e.TrackID, &e.DurationMs, &e.PlayedMs, &e.Scrobbled); err != nil {
+			return nil, err
+		}
+		e.Timestamp = time.UnixMilli(timestampMs)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Ranked is one entry in a Stats leaderboard.
+type Ranked struct {
+	Name  string `json:"name"`
+	Plays int    `json:"plays"`
+}
+
+// Stats summarizes the top artists, tracks, and albums played since a
+// given time.
+type Stats struct {
+	Artists []Ranked `json:"artists"`
+	Tracks  []Ranked `json:"tracks"`
+	Albums  []Ranked `json:"albums"`
+}
+
+// Stats returns the top limit artists/tracks/albums by play count since
+// the given time.
+func (s *Store) Stats(ctx context.Context, since time.Time, limit int) (*Stats, error) {
+	artists, err := s.topBy(ctx, "artist", since, limit)
+	if err != nil {
+		return nil, err
+	}
+	tracks, err := s.topBy(ctx, "track", since, limit)
+	if err != nil {
+		return nil, err
+	}
+	albums, err := s.topBy(ctx, "album", since, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &Stats{Artists: artists, Tracks: tracks, Albums: albums}, nil
+}
+
+// topBy ranks distinct values of column by play count since a given time.
+// column is never user-supplied -- it's always one of the three literal
+// column names above -- so building the query with fmt.Sprintf-free string
+// concatenation here is safe.
+func (s *Store) topBy(ctx context.Context, column string, since time.Time, limit int) ([]Ranked, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+column+`, COUNT(*) AS plays
+		FROM plays
+		WHERE timestamp >= ?
+		GROUP BY `+column+`
+		ORDER BY plays DESC
+		LIMIT ?
+	`, since.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Ranked
+	for rows.Next() {
+		var r Ranked
+		if err := rows.Scan(&r.Name, &r.Plays); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,126 @@
+package history
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const lastFMBaseURL = "https://ws.audioscrobbler.com/2.0/"
+
+// LastFMScrobbler forwards now-playing and scrobble notifications to
+// Last.fm. It requires an API key/secret (from
+// https://www.last.fm/api/account/create) and a session key obtained out
+// of band via Last.fm's desktop auth flow -- this package only forwards
+// scrobbles, it doesn't perform that auth handshake itself.
+type LastFMScrobbler struct {
+	apiKey     string
+	secret     string
+	session    string
+	httpClient *http.Client
+}
+
+// NewLastFMScrobbler builds a LastFMScrobbler from an API key/secret pair
+// and an already-obtained session key.
+func NewLastFMScrobbler(apiKey, secret, session string) *LastFMScrobbler {
+	return &LastFMScrobbler{
+		apiKey:     apiKey,
+		secret:     secret,
+		session:    session,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NowPlaying tells Last.fm what's currently playing via
+// track.updateNowPlaying. Unlike Scrobble, this doesn't require the
+// scrobble threshold to have been crossed.
+func (s *LastFMScrobbler) NowPlaying(ctx context.Context, e Entry) error {
+	return s.call(ctx, url.Values{
+		"method":   {"track.updateNowPlaying"},
+		"track":    {e.Track},
+		"artist":   {e.Artist},
+		"album":    {e.Album},
+		"duration": {strconv.Itoa(e.DurationMs / 1000)},
+	})
+}
+
+// Scrobble submits a completed play via track.scrobble. Callers are
+// expected to have already checked Scrobbled(e.DurationMs, e.PlayedMs).
+func (s *LastFMScrobbler) Scrobble(ctx context.Context, e Entry) error {
+	return s.call(ctx, url.Values{
+		"method":    {"track.scrobble"},
+		"track":     {e.Track},
+		"artist":    {e.Artist},
+		"album":     {e.Album},
+		"duration":  {strconv.Itoa(e.DurationMs / 1000)},
+		"timestamp": {strconv.FormatInt(e.Timestamp.Unix(), 10)},
+	})
+}
+
+// call signs params with Last.fm's api_sig scheme and POSTs them.
+func (s *LastFMScrobbler) call(ctx context.Context, params url.Values) error {
+	params.Set("api_key", s.apiKey)
+	params.Set("sk", s.session)
+	params.Set("api_sig", s.sign(params))
+	params.Set("format", "json")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, lastFMBaseURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("lastfm: unexpected status %d", resp.StatusCode)
+	}
+
+	var out struct {
+		Error   int    `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return err
+	}
+	if out.Error != 0 {
+		return fmt.Errorf("lastfm: %s (code %d)", out.Message, out.Error)
+	}
+	return nil
+}
+
+// sign computes Last.fm's api_sig: every param (excluding format and
+// api_sig itself) sorted by key, concatenated as key+value with no
+// separators, then the shared secret appended, then MD5'd.
+func (s *LastFMScrobbler) sign(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		if k == "format" || k == "api_sig" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf []byte
+	for _, k := range keys {
+		buf = append(buf, k...)
+		buf = append(buf, params.Get(k)...)
+	}
+	buf = append(buf, s.secret...)
+
+	sum := md5.Sum(buf)
+	return hex.EncodeToString(sum[:])
+}
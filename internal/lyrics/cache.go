@@ -0,0 +1,54 @@
+package lyrics
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var unsafeFilenameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// diskCache stores fetched Lyrics as JSON files in a directory, next to the
+// album art cache, so lyrics survive a restart without re-querying
+// providers.
+type diskCache struct {
+	dir string
+}
+
+func newDiskCache(dir string) *diskCache {
+	return &diskCache{dir: dir}
+}
+
+func (c *diskCache) path(ref TrackRef) string {
+	name := unsafeFilenameChars.ReplaceAllString(strings.ToLower(ref.Artist+"-"+ref.Track+"-"+strconv.Itoa(ref.DurationMs)), "_")
+	return filepath.Join(c.dir, name+".lyrics.json")
+}
+
+func (c *diskCache) get(ref TrackRef) *Lyrics {
+	data, err := os.ReadFile(c.path(ref))
+	if err != nil {
+		return nil
+	}
+
+	var l Lyrics
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil
+	}
+	return &l
+}
+
+func (c *diskCache) set(ref TrackRef, l *Lyrics) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path(ref), data, 0644)
+}
@@ -0,0 +1,18 @@
+package lyrics
+
+import "os"
+
+// NewManagerFromEnv builds a Manager caching into cacheDir, from the
+// providers enabled by environment variables:
+//
+//   - lrclib is always enabled; it requires no API key.
+//   - MUSIXMATCH_API_KEY: if set, enables the Musixmatch provider.
+func NewManagerFromEnv(cacheDir string) *Manager {
+	providers := []Provider{NewLRCLibProvider()}
+
+	if apiKey := os.Getenv("MUSIXMATCH_API_KEY"); apiKey != "" {
+		providers = append(providers, NewMusixmatchProvider(apiKey))
+	}
+
+	return NewManager(cacheDir, providers...)
+}
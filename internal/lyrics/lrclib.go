@@ -0,0 +1,82 @@
+package lyrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const lrcLibBaseURL = "https://lrclib.net/api/get"
+
+// LRCLibProvider fetches lyrics from lrclib.net, a free, keyless
+// synchronized-lyrics database.
+type LRCLibProvider struct {
+	httpClient *http.Client
+}
+
+// NewLRCLibProvider builds an LRCLibProvider.
+func NewLRCLibProvider() *LRCLibProvider {
+	return &LRCLibProvider{httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Name implements Provider.
+func (p *LRCLibProvider) Name() string { return "lrclib" }
+
+type lrcLibResponse struct {
+	Instrumental bool   `json:"instrumental"`
+	PlainLyrics  string `json:"plainLyrics"`
+	SyncedLyrics string `json:"syncedLyrics"`
+}
+
+// Fetch implements Provider.
+func (p *LRCLibProvider) Fetch(ctx context.Context, ref TrackRef) (*Lyrics, error) {
+	params := url.Values{
+		"artist_name": {ref.Artist},
+		"track_name":  {ref.Track},
+	}
+	if ref.Album != "" {
+		params.Set("album_name", ref.Album)
+	}
+	if ref.DurationMs > 0 {
+		params.Set("duration", strconv.Itoa(ref.DurationMs/1000))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, lrcLibBaseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var out lrcLibResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+
+	if out.Instrumental {
+		return nil, nil
+	}
+	if out.SyncedLyrics != "" {
+		return ParseLRC(out.SyncedLyrics), nil
+	}
+	if out.PlainLyrics != "" {
+		return ParsePlain(out.PlainLyrics), nil
+	}
+
+	return nil, nil
+}
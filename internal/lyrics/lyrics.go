@@ -0,0 +1,61 @@
+// Package lyrics fetches time-synchronized (LRC) or plain-text lyrics for
+// the currently playing track and tracks which line is active as playback
+// progresses.
+package lyrics
+
+import "context"
+
+// TrackRef identifies the track to fetch lyrics for. DurationMs helps
+// providers (like lrclib) disambiguate between re-recordings/remasters of
+// the same title.
+type TrackRef struct {
+	Artist     string
+	Track      string
+	Album      string
+	DurationMs int
+}
+
+// LyricLine is a single line of lyrics, optionally timestamped.
+type LyricLine struct {
+	TimeMs int    `json:"time_ms"`
+	Text   string `json:"text"`
+}
+
+// Lyrics holds the lyrics for a track. When Synced is true, Lines carry
+// meaningful TimeMs offsets and can be used to highlight the active line;
+// when false, the provider only had plain text and every line's TimeMs is 0.
+type Lyrics struct {
+	Synced bool        `json:"synced"`
+	Lines  []LyricLine `json:"lines"`
+}
+
+// Provider is a single lyrics source.
+type Provider interface {
+	Name() string
+	Fetch(ctx context.Context, ref TrackRef) (*Lyrics, error)
+}
+
+// CurrentLine returns the index of the active line and the next line for a
+// synced Lyrics at the given playback position, or -1 if there is no such
+// line yet (or the lyrics aren't synced). The active line is the last one
+// whose TimeMs has passed; next is simply the line after it, or -1 if the
+// active line is the last one.
+func (l *Lyrics) CurrentLine(posMs int) (current, next int) {
+	current, next = -1, -1
+	if l == nil || !l.Synced {
+		return
+	}
+
+	for i, line := range l.Lines {
+		if line.TimeMs > posMs {
+			break
+		}
+		current = i
+	}
+
+	if current >= 0 && current+1 < len(l.Lines) {
+		next = current + 1
+	}
+
+	return
+}
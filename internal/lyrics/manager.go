@@ -0,0 +1,84 @@
+package lyrics
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+)
+
+var logger = log.New(log.Writer(), "lyrics: ", log.LstdFlags)
+
+// Manager fetches lyrics for a track from the first configured Provider
+// that has them, caching the result both in memory (for the lifetime of
+// the process) and on disk (across restarts).
+type Manager struct {
+	providers []Provider
+	disk      *diskCache
+
+	mu       sync.Mutex
+	inMemory map[string]*Lyrics
+}
+
+// NewManager builds a Manager from an explicit list of providers, tried in
+// order, and a directory to cache fetched lyrics in.
+func NewManager(cacheDir string, providers ...Provider) *Manager {
+	return &Manager{
+		providers: providers,
+		disk:      newDiskCache(cacheDir),
+		inMemory:  make(map[string]*Lyrics),
+	}
+}
+
+func memKey(ref TrackRef) string {
+	return ref.Artist + "|" + ref.Track + "|" + strconv.Itoa(ref.DurationMs)
+}
+
+// Get returns the lyrics for ref, trying the in-memory cache, then the disk
+// cache, then each provider in order. It returns nil, nil if no provider
+// had lyrics for the track.
+func (m *Manager) Get(ctx context.Context, ref TrackRef) (*Lyrics, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	key := memKey(ref)
+
+	m.mu.Lock()
+	if cached, ok := m.inMemory[key]; ok {
+		m.mu.Unlock()
+		return cached, nil
+	}
+	m.mu.Unlock()
+
+	if cached := m.disk.get(ref); cached != nil {
+		m.remember(key, cached)
+		return cached, nil
+	}
+
+	for _, provider := range m.providers {
+		l, err := provider.Fetch(ctx, ref)
+		if err != nil {
+			logger.Printf("%s: %v", provider.Name(), err)
+			continue
+		}
+		if l == nil {
+			continue
+		}
+
+		if err := m.disk.set(ref, l); err != nil {
+			logger.Printf("caching lyrics for %q: %v", ref.Track, err)
+		}
+		m.remember(key, l)
+		return l, nil
+	}
+
+	m.remember(key, nil)
+	return nil, nil
+}
+
+func (m *Manager) remember(key string, l *Lyrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inMemory[key] = l
+}
@@ -0,0 +1,82 @@
+package lyrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const musixmatchBaseURL = "https://api.musixmatch.com/ws/1.1/matcher.subtitle.get"
+
+// MusixmatchProvider fetches synchronized lyrics from the Musixmatch API.
+// It requires an API key, supplied via the MUSIXMATCH_API_KEY environment
+// variable.
+type MusixmatchProvider struct {
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewMusixmatchProvider builds a MusixmatchProvider using the given API key.
+func NewMusixmatchProvider(apiKey string) *MusixmatchProvider {
+	return &MusixmatchProvider{
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name implements Provider.
+func (p *MusixmatchProvider) Name() string { return "musixmatch" }
+
+type musixmatchResponse struct {
+	Message struct {
+		Header struct {
+			StatusCode int `json:"status_code"`
+		} `json:"header"`
+		Body struct {
+			Subtitle struct {
+				SubtitleBody string `json:"subtitle_body"`
+			} `json:"subtitle"`
+		} `json:"body"`
+	} `json:"message"`
+}
+
+// Fetch implements Provider.
+func (p *MusixmatchProvider) Fetch(ctx context.Context, ref TrackRef) (*Lyrics, error) {
+	params := url.Values{
+		"q_track":  {ref.Track},
+		"q_artist": {ref.Artist},
+		"format":   {"lrc"},
+		"apikey":   {p.apiKey},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, musixmatchBaseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var out musixmatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+
+	// status_code 404 means no match, 401 means a bad key; neither is
+	// worth surfacing as an error since other providers may still help.
+	if out.Message.Header.StatusCode != 200 || out.Message.Body.Subtitle.SubtitleBody == "" {
+		return nil, nil
+	}
+
+	return ParseLRC(out.Message.Body.Subtitle.SubtitleBody), nil
+}
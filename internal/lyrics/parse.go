@@ -0,0 +1,85 @@
+package lyrics
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var lrcTagRE = regexp.MustCompile(`^\[(\d+):(\d+(?:\.\d+)?)\]`)
+
+// ParseLRC parses standard `[mm:ss.xx]text` synchronized lyrics. A line may
+// carry more than one leading timestamp tag (e.g.
+// `[00:12.00][00:45.00]Chorus line`), common in LRC files for repeated
+// choruses -- each tag produces its own cue sharing the line's text. Lines
+// that don't start with a timestamp are ignored (LRC files often have
+// metadata tags like `[ar:Artist]` mixed in). If no line carries a
+// timestamp, ParseLRC falls back to treating the input as plain,
+// unsynchronized text.
+func ParseLRC(raw string) *Lyrics {
+	var lines []LyricLine
+
+	for _, rawLine := range strings.Split(raw, "\n") {
+		rawLine = strings.TrimRight(rawLine, "\r")
+
+		var timesMs []int
+		rest := rawLine
+		for {
+			match := lrcTagRE.FindStringSubmatch(rest)
+			if match == nil {
+				break
+			}
+
+			minutes, err := strconv.Atoi(match[1])
+			if err != nil {
+				break
+			}
+			seconds, err := strconv.ParseFloat(match[2], 64)
+			if err != nil {
+				break
+			}
+
+			timesMs = append(timesMs, minutes*60000+int(seconds*1000))
+			rest = rest[len(match[0]):]
+		}
+		if len(timesMs) == 0 {
+			continue
+		}
+
+		text := strings.TrimSpace(rest)
+		if text == "" {
+			continue
+		}
+
+		for _, timeMs := range timesMs {
+			lines = append(lines, LyricLine{TimeMs: timeMs, Text: text})
+		}
+	}
+
+	if len(lines) > 0 {
+		sort.Slice(lines, func(i, j int) bool { return lines[i].TimeMs < lines[j].TimeMs })
+		return &Lyrics{Synced: true, Lines: lines}
+	}
+
+	return ParsePlain(raw)
+}
+
+// ParsePlain wraps plain, unsynchronized lyric text as a Lyrics value, one
+// LyricLine per non-empty line, all at TimeMs 0.
+func ParsePlain(raw string) *Lyrics {
+	var lines []LyricLine
+	for _, rawLine := range strings.Split(raw, "\n") {
+		text := strings.TrimSpace(rawLine)
+		if text == "" {
+			continue
+		}
+		lines = append(lines, LyricLine{Text: text})
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return &Lyrics{Synced: false, Lines: lines}
+}
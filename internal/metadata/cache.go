@@ -0,0 +1,42 @@
+package metadata
+
+import (
+	"sync"
+	"time"
+)
+
+// ttlCache is a small in-memory cache keyed by string, where each entry
+// expires independently after the TTL it was stored with. Agents use this
+// to avoid re-fetching artist/album/track lookups on every poll tick, the
+// same way Navidrome caches its metadata agent responses.
+type ttlCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+func newTTLCache() *ttlCache {
+	return &ttlCache{entries: make(map[string]cacheEntry)}
+}
+
+func (c *ttlCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *ttlCache) set(key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = cacheEntry{value: value, expires: time.Now().Add(ttl)}
+}
@@ -0,0 +1,26 @@
+package metadata
+
+import "os"
+
+// NewManagerFromEnv builds a Manager from the agents enabled by environment
+// variables:
+//
+//   - LASTFM_API_KEY: if set, enables the Last.fm agent.
+//   - MUSICBRAINZ_ENABLED: if set to "false", disables the MusicBrainz
+//     agent; it is enabled by default since it requires no API key.
+//
+// If no agents end up enabled, the returned Manager's Enrich always returns
+// an empty Info.
+func NewManagerFromEnv() *Manager {
+	var agents []Agent
+
+	if apiKey := os.Getenv("LASTFM_API_KEY"); apiKey != "" {
+		agents = append(agents, NewLastFMAgent(apiKey))
+	}
+
+	if os.Getenv("MUSICBRAINZ_ENABLED") != "false" {
+		agents = append(agents, NewMusicBrainzAgent())
+	}
+
+	return NewManager(agents...)
+}
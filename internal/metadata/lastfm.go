@@ -0,0 +1,230 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	lastFMBaseURL = "https://ws.audioscrobbler.com/2.0/"
+
+	// TTLs mirror what Navidrome uses for its own metadata agents: artist
+	// bios and similar-artist lists change rarely, album write-ups barely
+	// ever, and track-level tags churn the most.
+	lastFMArtistTTL = 24 * time.Hour
+	lastFMAlbumTTL  = 7 * 24 * time.Hour
+	lastFMTrackTTL  = 24 * time.Hour
+)
+
+// LastFMAgent fetches track/album/artist metadata from the Last.fm API.
+// It requires an API key, obtained from https://www.last.fm/api/account/create
+// and supplied via the LASTFM_API_KEY environment variable.
+type LastFMAgent struct {
+	apiKey     string
+	httpClient *http.Client
+
+	artistCache *ttlCache
+	albumCache  *ttlCache
+	trackCache  *ttlCache
+}
+
+// NewLastFMAgent builds a LastFMAgent using the given API key.
+func NewLastFMAgent(apiKey string) *LastFMAgent {
+	return &LastFMAgent{
+		apiKey:      apiKey,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		artistCache: newTTLCache(),
+		albumCache:  newTTLCache(),
+		trackCache:  newTTLCache(),
+	}
+}
+
+// Name implements Agent.
+func (a *LastFMAgent) Name() string { return "lastfm" }
+
+// Enrich implements Agent.
+func (a *LastFMAgent) Enrich(ctx context.Context, ref TrackRef, info *Info) error {
+	if trackInfo, err := a.trackGetInfo(ctx, ref); err != nil {
+		return fmt.Errorf("track.getInfo: %w", err)
+	} else if trackInfo != nil {
+		info.TrackMBID = trackInfo.Track.MBID
+		info.Tags = tagNames(trackInfo.Track.TopTags.Tag)
+	}
+
+	if albumInfo, err := a.albumGetInfo(ctx, ref); err != nil {
+		return fmt.Errorf("album.getInfo: %w", err)
+	} else if albumInfo != nil {
+		info.AlbumMBID = albumInfo.Album.MBID
+		info.AlbumDescription = albumInfo.Album.Wiki.Summary
+	}
+
+	if artistInfo, err := a.artistGetInfo(ctx, ref); err != nil {
+		return fmt.Errorf("artist.getInfo: %w", err)
+	} else if artistInfo != nil {
+		info.ArtistMBID = artistInfo.Artist.MBID
+		info.ArtistBio = artistInfo.Artist.Bio.Summary
+		info.SimilarArtists = similarArtistNames(artistInfo.Artist.Similar.Artist)
+		if len(info.Tags) == 0 {
+			info.Tags = tagNames(artistInfo.Artist.Tags.Tag)
+		}
+	}
+
+	return nil
+}
+
+type lastFMTrackResponse struct {
+	Track struct {
+		MBID    string `json:"mbid"`
+		TopTags struct {
+			Tag []lastFMTag `json:"tag"`
+		} `json:"toptags"`
+	} `json:"track"`
+}
+
+type lastFMAlbumResponse struct {
+	Album struct {
+		MBID string `json:"mbid"`
+		Wiki struct {
+			Summary string `json:"summary"`
+		} `json:"wiki"`
+	} `json:"album"`
+}
+
+type lastFMArtistResponse struct {
+	Artist struct {
+		MBID string `json:"mbid"`
+		Bio  struct {
+			Summary string `json:"summary"`
+		} `json:"bio"`
+		Similar struct {
+			Artist []lastFMArtistRef `json:"artist"`
+		} `json:"similar"`
+		Tags struct {
+			Tag []lastFMTag `json:"tag"`
+		} `json:"tags"`
+	} `json:"artist"`
+}
+
+type lastFMTag struct {
+	Name string `json:"name"`
+}
+
+type lastFMArtistRef struct {
+	Name string `json:"name"`
+}
+
+func tagNames(tags []lastFMTag) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = t.Name
+	}
+	return names
+}
+
+func similarArtistNames(artists []lastFMArtistRef) []string {
+	if len(artists) == 0 {
+		return nil
+	}
+	names := make([]string, len(artists))
+	for i, a := range artists {
+		names[i] = a.Name
+	}
+	return names
+}
+
+func (a *LastFMAgent) trackGetInfo(ctx context.Context, ref TrackRef) (*lastFMTrackResponse, error) {
+	if ref.Track == "" || ref.Artist == "" {
+		return nil, nil
+	}
+	key := "track|" + ref.Artist + "|" + ref.Track
+	if cached, ok := a.trackCache.get(key); ok {
+		return cached.(*lastFMTrackResponse), nil
+	}
+
+	out := &lastFMTrackResponse{}
+	if err := a.call(ctx, url.Values{
+		"method": {"track.getInfo"},
+		"artist": {ref.Artist},
+		"track":  {ref.Track},
+	}, out); err != nil {
+		return nil, err
+	}
+
+	a.trackCache.set(key, out, lastFMTrackTTL)
+	return out, nil
+}
+
+func (a *LastFMAgent) albumGetInfo(ctx context.Context, ref TrackRef) (*lastFMAlbumResponse, error) {
+	if ref.Album == "" || ref.Artist == "" {
+		return nil, nil
+	}
+	key := "album|" + ref.Artist + "|" + ref.Album
+	if cached, ok := a.albumCache.get(key); ok {
+		return cached.(*lastFMAlbumResponse), nil
+	}
+
+	out := &lastFMAlbumResponse{}
+	if err := a.call(ctx, url.Values{
+		"method": {"album.getInfo"},
+		"artist": {ref.Artist},
+		"album":  {ref.Album},
+	}, out); err != nil {
+		return nil, err
+	}
+
+	a.albumCache.set(key, out, lastFMAlbumTTL)
+	return out, nil
+}
+
+func (a *LastFMAgent) artistGetInfo(ctx context.Context, ref TrackRef) (*lastFMArtistResponse, error) {
+	if ref.Artist == "" {
+		return nil, nil
+	}
+	key := "artist|" + ref.Artist
+	if cached, ok := a.artistCache.get(key); ok {
+		return cached.(*lastFMArtistResponse), nil
+	}
+
+	out := &lastFMArtistResponse{}
+	if err := a.call(ctx, url.Values{
+		"method": {"artist.getInfo"},
+		"artist": {ref.Artist},
+	}, out); err != nil {
+		return nil, err
+	}
+
+	a.artistCache.set(key, out, lastFMArtistTTL)
+	return out, nil
+}
+
+func (a *LastFMAgent) call(ctx context.Context, params url.Values, out interface{}) error {
+	params.Set("api_key", a.apiKey)
+	params.Set("format", "json")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, lastFMBaseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
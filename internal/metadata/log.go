@@ -0,0 +1,9 @@
+package metadata
+
+import "log"
+
+var logger = log.New(log.Writer(), "metadata: ", log.LstdFlags)
+
+func logf(format string, args ...interface{}) {
+	logger.Printf(format, args...)
+}
@@ -0,0 +1,103 @@
+// Package metadata enriches a now-playing track with supplementary
+// information (biographies, MusicBrainz identifiers, similar artists, tags)
+// pulled from external music-information agents such as Last.fm and
+// MusicBrainz.
+package metadata
+
+import "context"
+
+// TrackRef identifies the track/album/artist currently playing, by Spotify
+// ID where available and by name as a fallback for agents that don't accept
+// Spotify IDs directly.
+type TrackRef struct {
+	SpotifyTrackID  string
+	SpotifyAlbumID  string
+	SpotifyArtistID string
+	Track           string
+	Album           string
+	Artist          string
+}
+
+// Info holds whatever supplementary metadata the enabled agents were able to
+// find. Every field is optional; an agent leaves a field empty rather than
+// erroring when it has nothing to contribute.
+type Info struct {
+	TrackMBID        string   `json:"track_mbid,omitempty"`
+	AlbumMBID        string   `json:"album_mbid,omitempty"`
+	ArtistMBID       string   `json:"artist_mbid,omitempty"`
+	ArtistBio        string   `json:"artist_bio,omitempty"`
+	AlbumDescription string   `json:"album_description,omitempty"`
+	SimilarArtists   []string `json:"similar_artists,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+	WikiSummary      string   `json:"wiki_summary,omitempty"`
+}
+
+// Agent is a single metadata provider. Enrich should fill in whatever fields
+// of info it can and return a non-nil error only when the lookup itself
+// failed (network, auth, etc.) -- a provider simply having no data for the
+// track is not an error.
+type Agent interface {
+	Name() string
+	Enrich(ctx context.Context, ref TrackRef, info *Info) error
+}
+
+// merge copies every non-empty field of src into dst without clobbering
+// fields dst already has, so earlier agents in the list take precedence.
+func merge(dst, src *Info) {
+	if dst.TrackMBID == "" {
+		dst.TrackMBID = src.TrackMBID
+	}
+	if dst.AlbumMBID == "" {
+		dst.AlbumMBID = src.AlbumMBID
+	}
+	if dst.ArtistMBID == "" {
+		dst.ArtistMBID = src.ArtistMBID
+	}
+	if dst.ArtistBio == "" {
+		dst.ArtistBio = src.ArtistBio
+	}
+	if dst.AlbumDescription == "" {
+		dst.AlbumDescription = src.AlbumDescription
+	}
+	if len(dst.SimilarArtists) == 0 {
+		dst.SimilarArtists = src.SimilarArtists
+	}
+	if len(dst.Tags) == 0 {
+		dst.Tags = src.Tags
+	}
+	if dst.WikiSummary == "" {
+		dst.WikiSummary = src.WikiSummary
+	}
+}
+
+// Manager queries every configured Agent for a track and merges their
+// results into a single Info, logging and skipping any agent that fails.
+type Manager struct {
+	agents []Agent
+}
+
+// NewManager builds a Manager from an explicit list of agents, in priority
+// order: earlier agents win when two agents supply the same field.
+func NewManager(agents ...Agent) *Manager {
+	return &Manager{agents: agents}
+}
+
+// Enrich queries every configured agent and returns the merged result. It
+// never returns nil, even if every agent fails or no agents are configured.
+func (m *Manager) Enrich(ctx context.Context, ref TrackRef) *Info {
+	info := &Info{}
+	if m == nil {
+		return info
+	}
+
+	for _, agent := range m.agents {
+		agentInfo := &Info{}
+		if err := agent.Enrich(ctx, ref, agentInfo); err != nil {
+			logf("%s: %v", agent.Name(), err)
+			continue
+		}
+		merge(info, agentInfo)
+	}
+
+	return info
+}
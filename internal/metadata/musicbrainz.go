@@ -0,0 +1,148 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	musicBrainzBaseURL = "https://musicbrainz.org/ws/2/"
+	musicBrainzTTL     = 7 * 24 * time.Hour
+
+	// MusicBrainz asks that clients identify themselves with a descriptive
+	// User-Agent; see https://musicbrainz.org/doc/MusicBrainz_API/Rate_Limiting.
+	musicBrainzUserAgent = "spotifymonitor/1.0 ( https://github.com/emmaly/spotifymonitor )"
+
+	// musicBrainzRateLimit is the unauthenticated rate MusicBrainz's own
+	// rate-limiting doc allows (~1 req/sec); a burst of 1 means we never
+	// fire two requests back to back even if several searches are due at
+	// once.
+	musicBrainzRateLimit = 1 * time.Second
+)
+
+// MusicBrainzAgent resolves MusicBrainz identifiers (MBIDs) for the
+// currently playing recording, release group, and artist by searching on
+// artist/track/album name, since Spotify IDs don't map directly to
+// MusicBrainz ones.
+type MusicBrainzAgent struct {
+	httpClient *http.Client
+	cache      *ttlCache
+	limiter    *rate.Limiter
+}
+
+// NewMusicBrainzAgent builds a MusicBrainzAgent. No API key is required.
+func NewMusicBrainzAgent() *MusicBrainzAgent {
+	return &MusicBrainzAgent{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		cache:      newTTLCache(),
+		limiter:    rate.NewLimiter(rate.Every(musicBrainzRateLimit), 1),
+	}
+}
+
+// Name implements Agent.
+func (a *MusicBrainzAgent) Name() string { return "musicbrainz" }
+
+// Enrich implements Agent.
+func (a *MusicBrainzAgent) Enrich(ctx context.Context, ref TrackRef, info *Info) error {
+	if ref.Track != "" && ref.Artist != "" {
+		mbid, err := a.search(ctx, "recording", fmt.Sprintf(`recording:"%s" AND artist:"%s"`, ref.Track, ref.Artist))
+		if err != nil {
+			return fmt.Errorf("recording search: %w", err)
+		}
+		info.TrackMBID = mbid
+	}
+
+	if ref.Album != "" && ref.Artist != "" {
+		mbid, err := a.search(ctx, "release-group", fmt.Sprintf(`releasegroup:"%s" AND artist:"%s"`, ref.Album, ref.Artist))
+		if err != nil {
+			return fmt.Errorf("release-group search: %w", err)
+		}
+		info.AlbumMBID = mbid
+	}
+
+	if ref.Artist != "" {
+		mbid, err := a.search(ctx, "artist", fmt.Sprintf(`artist:"%s"`, ref.Artist))
+		if err != nil {
+			return fmt.Errorf("artist search: %w", err)
+		}
+		info.ArtistMBID = mbid
+	}
+
+	return nil
+}
+
+type musicBrainzSearchResponse struct {
+	Recordings    []musicBrainzMatch `json:"recordings"`
+	ReleaseGroups []musicBrainzMatch `json:"release-groups"`
+	Artists       []musicBrainzMatch `json:"artists"`
+}
+
+type musicBrainzMatch struct {
+	ID string `json:"id"`
+}
+
+// search looks up the best match for an entity type ("recording",
+// "release-group", or "artist") and returns its MBID, or "" if nothing
+// matched.
+func (a *MusicBrainzAgent) search(ctx context.Context, entity, query string) (string, error) {
+	key := entity + "|" + query
+	if cached, ok := a.cache.get(key); ok {
+		return cached.(string), nil
+	}
+
+	if err := a.limiter.Wait(ctx); err != nil {
+		return "", err
+	}
+
+	params := url.Values{
+		"query": {query},
+		"fmt":   {"json"},
+		"limit": {"1"},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, musicBrainzBaseURL+entity+"/?"+params.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", musicBrainzUserAgent)
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var out musicBrainzSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+
+	mbid := ""
+	switch entity {
+	case "recording":
+		if len(out.Recordings) > 0 {
+			mbid = out.Recordings[0].ID
+		}
+	case "release-group":
+		if len(out.ReleaseGroups) > 0 {
+			mbid = out.ReleaseGroups[0].ID
+		}
+	case "artist":
+		if len(out.Artists) > 0 {
+			mbid = out.Artists[0].ID
+		}
+	}
+
+	a.cache.set(key, mbid, musicBrainzTTL)
+	return mbid, nil
+}
@@ -0,0 +1,283 @@
+// Package palette extracts a small, representative color palette from an
+// image using k-means clustering in CIE L*a*b* space, so that perceptually
+// similar colors cluster together the way the human eye groups them,
+// unlike clustering in raw sRGB.
+package palette
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+const (
+	// DefaultK is the default number of palette entries to extract.
+	DefaultK = 5
+
+	// maxSampleDim downsamples the source image to roughly this many
+	// pixels on its longest side before clustering, so a few hundred
+	// thousand pixels of album art don't turn k-means into the
+	// bottleneck of every poll tick.
+	maxSampleDim = 100
+
+	kMeansIterations = 20
+)
+
+// Role describes what part of the UI a palette entry is best suited for.
+type Role string
+
+const (
+	RoleBackground Role = "background"
+	RoleAccent     Role = "accent"
+	RoleMuted      Role = "muted"
+)
+
+// Swatch is one color in an extracted palette.
+type Swatch struct {
+	RGB           color.RGBA
+	PopulationPct float64
+	Role          Role
+}
+
+// Extract downsamples img to ~100px on its longest side, clusters its
+// pixels in LAB space with k-means++ seeding (k clusters, 20 iterations),
+// and returns one Swatch per non-empty cluster. Swatches are ordered by
+// population weighted by saturation, so a few vivid accent pixels aren't
+// drowned out by a much larger muted background -- the most
+// vivid-and-common cluster becomes the "accent", the largest cluster
+// overall becomes the "background", and everything else is "muted".
+func Extract(img image.Image, k int) []Swatch {
+	if k <= 0 {
+		k = DefaultK
+	}
+
+	points := sampleLab(img)
+	if len(points) == 0 {
+		return nil
+	}
+	if k > len(points) {
+		k = len(points)
+	}
+
+	assignments, centroids := kMeans(points, k, kMeansIterations)
+	return buildSwatches(points, assignments, centroids)
+}
+
+type candidate struct {
+	rgb           color.RGBA
+	populationPct float64
+	chroma        float64
+}
+
+func buildSwatches(points [][3]float64, assignments []int, centroids [][3]float64) []Swatch {
+	counts := make([]int, len(centroids))
+	for _, a := range assignments {
+		counts[a]++
+	}
+
+	candidates := make([]candidate, 0, len(centroids))
+	maxChroma := 0.0
+	for i, centroid := range centroids {
+		if counts[i] == 0 {
+			continue
+		}
+
+		chroma := math.Hypot(centroid[1], centroid[2])
+		if chroma > maxChroma {
+			maxChroma = chroma
+		}
+
+		r, g, b := colorful.Lab(centroid[0], centroid[1], centroid[2]).Clamped().RGB255()
+		candidates = append(candidates, candidate{
+			rgb:           color.RGBA{R: r, G: g, B: b, A: 255},
+			populationPct: float64(counts[i]) / float64(len(points)) * 100,
+			chroma:        chroma,
+		})
+	}
+
+	backgroundIdx := 0
+	for i, c := range candidates {
+		if c.populationPct > candidates[backgroundIdx].populationPct {
+			backgroundIdx = i
+		}
+	}
+	background := candidates[backgroundIdx]
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return weightedScore(candidates[i], maxChroma) > weightedScore(candidates[j], maxChroma)
+	})
+
+	swatches := make([]Swatch, len(candidates))
+	accentAssigned := false
+	for i, c := range candidates {
+		role := RoleMuted
+		switch {
+		case c == background:
+			role = RoleBackground
+		case !accentAssigned:
+			role = RoleAccent
+			accentAssigned = true
+		}
+		swatches[i] = Swatch{RGB: c.rgb, PopulationPct: c.populationPct, Role: role}
+	}
+
+	return swatches
+}
+
+// weightedScore favors clusters that are both common and saturated, so a
+// large expanse of muted background doesn't automatically outrank a
+// smaller but vivid accent color.
+func weightedScore(c candidate, maxChroma float64) float64 {
+	normalizedChroma := 0.0
+	if maxChroma > 0 {
+		normalizedChroma = c.chroma / maxChroma
+	}
+	return c.populationPct * (0.3 + 0.7*normalizedChroma)
+}
+
+// sampleLab downsamples img to ~maxSampleDim pixels on its longest side
+// and converts each sampled pixel to CIE L*a*b*.
+func sampleLab(img image.Image) [][3]float64 {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w == 0 || h == 0 {
+		return nil
+	}
+
+	scale := 1.0
+	if longest := w; h > w {
+		longest = h
+		if longest > maxSampleDim {
+			scale = float64(maxSampleDim) / float64(longest)
+		}
+	} else if longest > maxSampleDim {
+		scale = float64(maxSampleDim) / float64(longest)
+	}
+
+	sampledW := max(1, int(float64(w)*scale))
+	sampledH := max(1, int(float64(h)*scale))
+
+	points := make([][3]float64, 0, sampledW*sampledH)
+	for sy := 0; sy < sampledH; sy++ {
+		srcY := bounds.Min.Y + sy*h/sampledH
+		for sx := 0; sx < sampledW; sx++ {
+			srcX := bounds.Min.X + sx*w/sampledW
+			c, ok := colorful.MakeColor(img.At(srcX, srcY))
+			if !ok {
+				continue
+			}
+			l, a, b := c.Lab()
+			points = append(points, [3]float64{l, a, b})
+		}
+	}
+
+	return points
+}
+
+func kMeans(points [][3]float64, k, iterations int) (assignments []int, centroids [][3]float64) {
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	centroids = seedPlusPlus(points, k, rnd)
+	assignments = make([]int, len(points))
+
+	for iter := 0; iter < iterations; iter++ {
+		for i, p := range points {
+			assignments[i] = nearestCentroid(p, centroids)
+		}
+
+		sums := make([][3]float64, k)
+		counts := make([]int, k)
+		for i, p := range points {
+			a := assignments[i]
+			sums[a][0] += p[0]
+			sums[a][1] += p[1]
+			sums[a][2] += p[2]
+			counts[a]++
+		}
+
+		for i := range centroids {
+			if counts[i] == 0 {
+				continue // keep the previous centroid for a cluster nothing landed in
+			}
+			centroids[i] = [3]float64{
+				sums[i][0] / float64(counts[i]),
+				sums[i][1] / float64(counts[i]),
+				sums[i][2] / float64(counts[i]),
+			}
+		}
+	}
+
+	return assignments, centroids
+}
+
+// seedPlusPlus picks initial centroids via k-means++: a random first
+// point, then each subsequent centroid chosen with probability
+// proportional to its squared distance from the nearest existing
+// centroid, which spreads the seeds out and converges faster/better than
+// picking k random points.
+func seedPlusPlus(points [][3]float64, k int, rnd *rand.Rand) [][3]float64 {
+	centroids := make([][3]float64, 0, k)
+	centroids = append(centroids, points[rnd.Intn(len(points))])
+
+	distSq := make([]float64, len(points))
+	for len(centroids) < k {
+		var total float64
+		for i, p := range points {
+			d := distSqTo(p, centroids)
+			distSq[i] = d
+			total += d
+		}
+
+		if total == 0 {
+			centroids = append(centroids, points[rnd.Intn(len(points))])
+			continue
+		}
+
+		target := rnd.Float64() * total
+		var cum float64
+		chosen := points[len(points)-1]
+		for i, d := range distSq {
+			cum += d
+			if cum >= target {
+				chosen = points[i]
+				break
+			}
+		}
+		centroids = append(centroids, chosen)
+	}
+
+	return centroids
+}
+
+func nearestCentroid(p [3]float64, centroids [][3]float64) int {
+	best := 0
+	bestDist := distSq(p, centroids[0])
+	for i := 1; i < len(centroids); i++ {
+		if d := distSq(p, centroids[i]); d < bestDist {
+			best = i
+			bestDist = d
+		}
+	}
+	return best
+}
+
+func distSqTo(p [3]float64, centroids [][3]float64) float64 {
+	best := distSq(p, centroids[0])
+	for i := 1; i < len(centroids); i++ {
+		if d := distSq(p, centroids[i]); d < best {
+			best = d
+		}
+	}
+	return best
+}
+
+func distSq(a, b [3]float64) float64 {
+	dl := a[0] - b[0]
+	da := a[1] - b[1]
+	db := a[2] - b[2]
+	return dl*dl + da*da + db*db
+}
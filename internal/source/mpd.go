@@ -0,0 +1,114 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MPDSource reports playback from an MPD (Music Player Daemon) instance,
+// speaking its line-based text protocol directly -- a new connection is
+// opened for each poll since MPD connections are cheap and this avoids
+// having to deal with a dropped idle connection between ticks.
+type MPDSource struct {
+	addr string
+}
+
+// NewMPDSource builds an MPDSource connecting to addr (host:port).
+func NewMPDSource(addr string) *MPDSource {
+	return &MPDSource{addr: addr}
+}
+
+// NewMPDSourceFromEnv builds an MPDSource from MPD_HOST (default
+// "localhost") and MPD_PORT (default "6600").
+func NewMPDSourceFromEnv() *MPDSource {
+	host := os.Getenv("MPD_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+	port := os.Getenv("MPD_PORT")
+	if port == "" {
+		port = "6600"
+	}
+	return NewMPDSource(net.JoinHostPort(host, port))
+}
+
+// CurrentlyPlaying implements PlayerSource.
+func (s *MPDSource) CurrentlyPlaying(ctx context.Context) (*NowPlaying, error) {
+	conn, err := net.DialTimeout("tcp", s.addr, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	if _, err := reader.ReadString('\n'); err != nil { // banner: "OK MPD <version>"
+		return nil, err
+	}
+
+	status, err := mpdCommand(conn, reader, "status")
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := mpdCommand(conn, reader, "currentsong")
+	if err != nil {
+		return nil, err
+	}
+
+	np := &NowPlaying{
+		Playing:  status["state"] == "play",
+		Track:    current["Title"],
+		TrackID:  current["file"],
+		Album:    current["Album"],
+		Artist:   current["Artist"],
+		ArtistID: current["Artist"],
+	}
+
+	if elapsed, err := strconv.ParseFloat(status["elapsed"], 64); err == nil {
+		np.Progress = int(elapsed * 1000)
+	}
+	if duration, err := strconv.ParseFloat(status["duration"], 64); err == nil {
+		np.Duration = int(duration * 1000)
+	}
+
+	return np, nil
+}
+
+// Close implements PlayerSource. MPDSource dials fresh per poll, so there's
+// nothing to hold open.
+func (s *MPDSource) Close() error { return nil }
+
+// mpdCommand sends a single command and reads its "key: value" response
+// lines until the terminating "OK" (or "ACK ..." on error).
+func mpdCommand(conn net.Conn, reader *bufio.Reader, cmd string) (map[string]string, error) {
+	if _, err := fmt.Fprintf(conn, "%s\n", cmd); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "OK" {
+			return fields, nil
+		}
+		if strings.HasPrefix(line, "ACK ") {
+			return nil, fmt.Errorf("mpd: %s", line)
+		}
+
+		key, value, found := strings.Cut(line, ": ")
+		if found {
+			fields[key] = value
+		}
+	}
+}
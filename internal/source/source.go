@@ -0,0 +1,30 @@
+// Package source abstracts over the music player being monitored, so the
+// rest of spotifymonitor can report on Spotify, a Subsonic/Navidrome
+// server, or an MPD instance without caring which one is active.
+package source
+
+import "context"
+
+// NowPlaying is a backend-agnostic snapshot of what a PlayerSource last saw
+// playing. Timestamp is left for the caller to stamp, since it represents
+// when the snapshot was taken locally, not anything the backend reports.
+type NowPlaying struct {
+	Playing     bool
+	Timestamp   int64
+	Progress    int
+	Duration    int
+	Track       string
+	TrackID     string
+	Album       string
+	AlbumID     string
+	Artist      string
+	ArtistID    string
+	AlbumArtURL string
+	Endpoint    string
+}
+
+// PlayerSource is a backend that can report what's currently playing.
+type PlayerSource interface {
+	CurrentlyPlaying(ctx context.Context) (*NowPlaying, error)
+	Close() error
+}
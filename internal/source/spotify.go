@@ -0,0 +1,60 @@
+package source
+
+import (
+	"context"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// SpotifySource reports playback from a Spotify account via an already
+// authenticated client.
+type SpotifySource struct {
+	client *spotify.Client
+}
+
+// NewSpotifySource wraps an authenticated Spotify client as a PlayerSource.
+func NewSpotifySource(client *spotify.Client) *SpotifySource {
+	return &SpotifySource{client: client}
+}
+
+// CurrentlyPlaying implements PlayerSource.
+func (s *SpotifySource) CurrentlyPlaying(ctx context.Context) (*NowPlaying, error) {
+	state, err := s.client.PlayerCurrentlyPlaying(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil || state.Item == nil {
+		return &NowPlaying{}, nil
+	}
+
+	artist, artistID := "", ""
+	if len(state.Item.Artists) > 0 {
+		artist = state.Item.Artists[0].Name
+		artistID = string(state.Item.Artists[0].ID)
+	}
+
+	return &NowPlaying{
+		Playing:     state.Playing,
+		Progress:    state.Progress,
+		Duration:    state.Item.Duration,
+		Track:       state.Item.Name,
+		TrackID:     string(state.Item.ID),
+		Album:       state.Item.Album.Name,
+		AlbumID:     string(state.Item.Album.ID),
+		Artist:      artist,
+		ArtistID:    artistID,
+		AlbumArtURL: albumArtURL(state.Item.Album),
+		Endpoint:    state.PlaybackContext.Endpoint,
+	}, nil
+}
+
+// Close implements PlayerSource. The underlying spotify.Client owns no
+// closable resources.
+func (s *SpotifySource) Close() error { return nil }
+
+func albumArtURL(album spotify.SimpleAlbum) string {
+	if len(album.Images) > 0 {
+		return album.Images[0].URL
+	}
+	return ""
+}
@@ -0,0 +1,177 @@
+package source
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const subsonicAPIVersion = "1.16.1"
+
+// SubsonicSource reports playback from a Subsonic-compatible server (e.g.
+// Navidrome), authenticating with the salt+token scheme described at
+// https://www.subsonic.org/pages/api.jsp.
+type SubsonicSource struct {
+	baseURL    string
+	user       string
+	password   string
+	httpClient *http.Client
+}
+
+// NewSubsonicSource builds a SubsonicSource for the given server.
+func NewSubsonicSource(baseURL, user, password string) *SubsonicSource {
+	return &SubsonicSource{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		user:       user,
+		password:   password,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NewSubsonicSourceFromEnv builds a SubsonicSource from SUBSONIC_URL,
+// SUBSONIC_USER, and SUBSONIC_PASSWORD.
+func NewSubsonicSourceFromEnv() (*SubsonicSource, error) {
+	baseURL := os.Getenv("SUBSONIC_URL")
+	user := os.Getenv("SUBSONIC_USER")
+	password := os.Getenv("SUBSONIC_PASSWORD")
+	if baseURL == "" || user == "" || password == "" {
+		return nil, fmt.Errorf("SUBSONIC_URL, SUBSONIC_USER, and SUBSONIC_PASSWORD must all be set")
+	}
+	return NewSubsonicSource(baseURL, user, password), nil
+}
+
+type subsonicNowPlayingResponse struct {
+	SubsonicResponse struct {
+		Status     string `json:"status"`
+		NowPlaying struct {
+			Entry []subsonicNowPlayingEntry `json:"entry"`
+		} `json:"nowPlaying"`
+	} `json:"subsonic-response"`
+}
+
+type subsonicNowPlayingEntry struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Album      string `json:"album"`
+	AlbumID    string `json:"albumId"`
+	Artist     string `json:"artist"`
+	ArtistID   string `json:"artistId"`
+	Duration   int    `json:"duration"`
+	CoverArt   string `json:"coverArt"`
+	Username   string `json:"username"`
+	MinutesAgo int    `json:"minutesAgo"`
+}
+
+// CurrentlyPlaying implements PlayerSource. Subsonic's getNowPlaying.view
+// reports what every user on the server is playing, not just ours, and
+// doesn't expose a live playback position -- only "minutes ago", which we
+// use as a rough approximation of elapsed progress.
+func (s *SubsonicSource) CurrentlyPlaying(ctx context.Context) (*NowPlaying, error) {
+	var out subsonicNowPlayingResponse
+	if err := s.call(ctx, "getNowPlaying", nil, &out); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range out.SubsonicResponse.NowPlaying.Entry {
+		if entry.Username != "" && entry.Username != s.user {
+			continue
+		}
+
+		return &NowPlaying{
+			Playing:     true,
+			Progress:    entry.MinutesAgo * 60 * 1000,
+			Duration:    entry.Duration * 1000,
+			Track:       entry.Title,
+			TrackID:     entry.ID,
+			Album:       entry.Album,
+			AlbumID:     entry.AlbumID,
+			Artist:      entry.Artist,
+			ArtistID:    entry.ArtistID,
+			AlbumArtURL: s.coverArtURL(entry.CoverArt),
+		}, nil
+	}
+
+	return &NowPlaying{}, nil
+}
+
+// Close implements PlayerSource. SubsonicSource holds no closable resources.
+func (s *SubsonicSource) Close() error { return nil }
+
+func (s *SubsonicSource) coverArtURL(coverArt string) string {
+	if coverArt == "" {
+		return ""
+	}
+
+	params, err := s.authParams()
+	if err != nil {
+		return ""
+	}
+	params.Set("id", coverArt)
+
+	return s.baseURL + "/rest/getCoverArt.view?" + params.Encode()
+}
+
+func (s *SubsonicSource) call(ctx context.Context, endpoint string, extra url.Values, out interface{}) error {
+	params, err := s.authParams()
+	if err != nil {
+		return err
+	}
+	for key, values := range extra {
+		for _, value := range values {
+			params.Add(key, value)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/rest/"+endpoint+".view?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("subsonic: unexpected status %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// authParams builds the u/t/s/v/c/f query parameters Subsonic's
+// salt+token auth scheme requires: t = md5(password + salt).
+func (s *SubsonicSource) authParams() (url.Values, error) {
+	salt, err := randomSalt()
+	if err != nil {
+		return nil, err
+	}
+
+	hash := md5.Sum([]byte(s.password + salt))
+
+	return url.Values{
+		"u": {s.user},
+		"t": {hex.EncodeToString(hash[:])},
+		"s": {salt},
+		"v": {subsonicAPIVersion},
+		"c": {"spotifymonitor"},
+		"f": {"json"},
+	}, nil
+}
+
+func randomSalt() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -20,21 +20,34 @@ import (
 	"sync"
 	"time"
 
+	"github.com/emmaly/spotifymonitor/internal/history"
+	"github.com/emmaly/spotifymonitor/internal/lyrics"
+	"github.com/emmaly/spotifymonitor/internal/metadata"
+	"github.com/emmaly/spotifymonitor/internal/palette"
+	"github.com/emmaly/spotifymonitor/internal/source"
 	"github.com/gorilla/websocket"
 	"github.com/joho/godotenv"
 	"github.com/lucasb-eyer/go-colorful"
-	color_extractor "github.com/marekm4/color-extractor"
 	"github.com/zmb3/spotify/v2"
 	spotifyauth "github.com/zmb3/spotify/v2/auth"
 	"golang.org/x/oauth2"
 )
 
 var (
-	currentState  *spotify.CurrentlyPlaying
-	stateMutex    sync.Mutex
-	imageCacheDir string
-	reportURL     string
-	upgrader      = websocket.Upgrader{
+	currentState    *source.NowPlaying
+	stateMutex      sync.Mutex
+	imageCacheDir   string
+	reportURL       string
+	metadataManager *metadata.Manager
+	lyricsManager   *lyrics.Manager
+	currentLyrics   *lyrics.Lyrics
+	currentMetadata = &metadata.Info{}
+	currentSwatches []palette.Swatch
+	historyStore    *history.Store
+	scrobbler       *history.LastFMScrobbler
+	contrastMode    string
+	paletteK        int
+	upgrader        = websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 	}
@@ -50,49 +63,25 @@ func main() {
 		imageCacheDir = "image_cache"
 	}
 	reportURL = os.Getenv("REPORT_URL")
-
-	logger := log.New(os.Stdout, "spotify-reporter: ", log.LstdFlags)
-
-	auth := spotifyauth.New(
-		spotifyauth.WithClientID(os.Getenv("SPOTIFY_CLIENT_ID")),
-		spotifyauth.WithClientSecret(os.Getenv("SPOTIFY_CLIENT_SECRET")),
-		spotifyauth.WithRedirectURL(os.Getenv("SPOTIFY_REDIRECT_URL")),
-		spotifyauth.WithScopes(spotifyauth.ScopeUserReadPlaybackState),
-	)
-
-	// Get the authentication URL
-	url := auth.AuthURL("state")
-	fmt.Println("Please visit this URL to authorize the application:", url)
-
-	// Set up a web server to handle the OAuth callback
-	ch := make(chan *oauth2.Token)
-	tokenReceiverHandler := func(w http.ResponseWriter, r *http.Request) {
-		logger.Println("Received request:", r.URL.String())
-
-		token, err := auth.Token(r.Context(), "state", r)
-		if err != nil {
-			http.Error(w, "Couldn't get token", http.StatusForbidden)
-			logger.Fatal(err)
+	contrastMode = os.Getenv("CONTRAST_MODE")
+	paletteK = palette.DefaultK
+	if v := os.Getenv("PALETTE_K"); v != "" {
+		if k, err := strconv.Atoi(v); err == nil && k > 0 {
+			paletteK = k
 		}
+	}
+	metadataManager = metadata.NewManagerFromEnv()
+	lyricsManager = lyrics.NewManagerFromEnv(imageCacheDir)
 
-		if st := r.FormValue("state"); st != "state" {
-			http.NotFound(w, r)
-			logger.Fatalf("State mismatch: %s != state", st)
-		}
-
-		// Print the token details
-		logger.Printf("Token type: %s\n", token.TokenType)
-		logger.Printf("Expires in: %d seconds\n", token.Expiry.Unix()-time.Now().Unix())
-
-		// Display a success message
-		w.Header().Set("Content-Type", "text/html")
-		fmt.Fprintf(w, "Success! You can now close this window. <a href='./'>./</a>")
+	logger := log.New(os.Stdout, "spotify-reporter: ", log.LstdFlags)
 
-		// Send the token through a channel
-		ch <- token
+	var err error
+	historyStore, err = history.NewStoreFromEnv()
+	if err != nil {
+		logger.Fatal(err)
 	}
-	http.HandleFunc("/callback", tokenReceiverHandler)
-	http.HandleFunc("/_spotifymonitor/callback", tokenReceiverHandler)
+	defer historyStore.Close()
+	scrobbler = history.NewScrobblerFromEnv()
 
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		state := getCurrentStatus(currentState)
@@ -107,14 +96,21 @@ func main() {
 
 	http.HandleFunc("/ws", handleWebSocket)
 	http.HandleFunc("/_spotifymonitor/ws", handleWebSocket)
+	http.HandleFunc("/history", handleHistory)
+	http.HandleFunc("/history.csv", handleHistoryCSV)
+	http.HandleFunc("/scrobble", handleScrobble)
+	http.HandleFunc("/stats", handleStats)
 
 	go http.ListenAndServe(":"+httpPort, nil)
 
-	// Wait for the user to authorize the application and get the token
-	token := <-ch
-
-	// Create a new Spotify client using the token
-	client := spotify.New(auth.Client(context.Background(), token))
+	// Set up the active music-source backend (Spotify requires completing
+	// the OAuth dance via the web server started above; the others connect
+	// directly using their own credentials).
+	playerSource, err := newPlayerSource(logger)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	defer playerSource.Close()
 
 	// Start a goroutine to send the current status every 1 second
 	go func() {
@@ -130,7 +126,7 @@ func main() {
 
 	for {
 		// Get the current playback state
-		state, err := client.PlayerCurrentlyPlaying(context.Background())
+		state, err := playerSource.CurrentlyPlaying(context.Background())
 		if err != nil {
 			fmt.Println("Error getting playback state:", err)
 			time.Sleep(5 * time.Second)
@@ -139,15 +135,161 @@ func main() {
 
 		// Update the shared state
 		stateMutex.Lock()
+		previousState := currentState
 		state.Timestamp = time.Now().UnixNano() / int64(time.Millisecond)
 		currentState = state
 		stateMutex.Unlock()
 
+		previousTrackID := ""
+		if previousState != nil {
+			previousTrackID = previousState.TrackID
+		}
+
+		// When the track changes, look up its lyrics once rather than on
+		// every tick of sendCurrentStatus, record the outgoing track to
+		// history, and tell Last.fm what's playing now.
+		if state.TrackID != previousTrackID {
+			if previousState != nil && previousState.TrackID != "" {
+				recordTrackHistory(previousState)
+			}
+
+			l, err := lyricsManager.Get(context.Background(), lyrics.TrackRef{
+				Artist:     state.Artist,
+				Track:      state.Track,
+				Album:      state.Album,
+				DurationMs: state.Duration,
+			})
+			if err != nil {
+				fmt.Println("Error fetching lyrics:", err)
+			}
+
+			// Likewise, look up supplementary metadata once per track
+			// change rather than on every 1-second status push -- on a
+			// cache miss this can be several blocking HTTP calls deep.
+			meta := metadataManager.Enrich(context.Background(), metadata.TrackRef{
+				SpotifyTrackID:  state.TrackID,
+				SpotifyAlbumID:  state.AlbumID,
+				SpotifyArtistID: state.ArtistID,
+				Track:           state.Track,
+				Album:           state.Album,
+				Artist:          state.Artist,
+			})
+
+			// And the dominant-color palette: downloading the art and
+			// running k-means clustering over it is far too expensive to
+			// repeat on every 1-second status push.
+			var swatches []palette.Swatch
+			if state.AlbumArtURL != "" {
+				albumArtFile, err := downloadAlbumArt(state.AlbumArtURL)
+				if err != nil {
+					fmt.Println("Error downloading album art:", err)
+				}
+				if albumArtFile != "" {
+					swatches = extractPalette(albumArtFile, paletteK)
+				}
+			}
+
+			stateMutex.Lock()
+			currentLyrics = l
+			currentMetadata = meta
+			currentSwatches = swatches
+			stateMutex.Unlock()
+
+			if state.TrackID != "" && scrobbler != nil {
+				go func(s *source.NowPlaying) {
+					if err := scrobbler.NowPlaying(context.Background(), history.Entry{
+						Track:      s.Track,
+						Album:      s.Album,
+						Artist:     s.Artist,
+						TrackID:    s.TrackID,
+						DurationMs: s.Duration,
+					}); err != nil {
+						fmt.Println("Error sending now-playing to Last.fm:", err)
+					}
+				}(state)
+			}
+		}
+
 		// Sleep for 5 seconds before the next query
 		time.Sleep(5 * time.Second)
 	}
 }
 
+// newPlayerSource picks and connects the active music-source backend based
+// on the SOURCE environment variable ("spotify", the default; "subsonic";
+// or "mpd").
+func newPlayerSource(logger *log.Logger) (source.PlayerSource, error) {
+	sourceName := os.Getenv("SOURCE")
+	if sourceName == "" {
+		sourceName = "spotify"
+	}
+
+	switch sourceName {
+	case "spotify":
+		return newSpotifySource(logger)
+	case "subsonic":
+		return source.NewSubsonicSourceFromEnv()
+	case "mpd":
+		return source.NewMPDSourceFromEnv(), nil
+	default:
+		return nil, fmt.Errorf("unknown SOURCE %q (want spotify, subsonic, or mpd)", sourceName)
+	}
+}
+
+// newSpotifySource runs the interactive OAuth flow (using the /callback
+// routes registered against the already-running web server) and returns a
+// PlayerSource backed by the resulting authenticated client.
+func newSpotifySource(logger *log.Logger) (source.PlayerSource, error) {
+	auth := spotifyauth.New(
+		spotifyauth.WithClientID(os.Getenv("SPOTIFY_CLIENT_ID")),
+		spotifyauth.WithClientSecret(os.Getenv("SPOTIFY_CLIENT_SECRET")),
+		spotifyauth.WithRedirectURL(os.Getenv("SPOTIFY_REDIRECT_URL")),
+		spotifyauth.WithScopes(spotifyauth.ScopeUserReadPlaybackState),
+	)
+
+	// Get the authentication URL
+	url := auth.AuthURL("state")
+	fmt.Println("Please visit this URL to authorize the application:", url)
+
+	// Set up a web server to handle the OAuth callback
+	ch := make(chan *oauth2.Token)
+	tokenReceiverHandler := func(w http.ResponseWriter, r *http.Request) {
+		logger.Println("Received request:", r.URL.String())
+
+		token, err := auth.Token(r.Context(), "state", r)
+		if err != nil {
+			http.Error(w, "Couldn't get token", http.StatusForbidden)
+			logger.Fatal(err)
+		}
+
+		if st := r.FormValue("state"); st != "state" {
+			http.NotFound(w, r)
+			logger.Fatalf("State mismatch: %s != state", st)
+		}
+
+		// Print the token details
+		logger.Printf("Token type: %s\n", token.TokenType)
+		logger.Printf("Expires in: %d seconds\n", token.Expiry.Unix()-time.Now().Unix())
+
+		// Display a success message
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, "Success! You can now close this window. <a href='./'>./</a>")
+
+		// Send the token through a channel
+		ch <- token
+	}
+	http.HandleFunc("/callback", tokenReceiverHandler)
+	http.HandleFunc("/_spotifymonitor/callback", tokenReceiverHandler)
+
+	// Wait for the user to authorize the application and get the token
+	token := <-ch
+
+	// Create a new Spotify client using the token
+	client := spotify.New(auth.Client(context.Background(), token))
+
+	return source.NewSpotifySource(client), nil
+}
+
 func handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -168,7 +310,130 @@ func handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func getCurrentStatus(state *spotify.CurrentlyPlaying) map[string]interface{} {
+// handleHistory serves recorded plays as JSON. Query params: limit (max
+// rows, default 100) and since (Unix milliseconds, default all time).
+func handleHistory(w http.ResponseWriter, r *http.Request) {
+	limit, since, err := parseHistoryQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := historyStore.List(r.Context(), limit, since)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// handleHistoryCSV serves recorded plays as CSV. Accepts the same limit
+// and since query params as handleHistory.
+func handleHistoryCSV(w http.ResponseWriter, r *http.Request) {
+	limit, since, err := parseHistoryQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := historyStore.List(r.Context(), limit, since)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	fmt.Fprintln(w, "timestamp,track,album,artist,track_id,duration_ms,played_ms,scrobbled")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s,%q,%q,%q,%q,%d,%d,%t\n",
+			e.Timestamp.Format(time.RFC3339), e.Track, e.Album, e.Artist, e.TrackID, e.DurationMs, e.PlayedMs, e.Scrobbled)
+	}
+}
+
+func parseHistoryQuery(r *http.Request) (limit int, since time.Time, err error) {
+	limit = 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil {
+			return 0, time.Time{}, fmt.Errorf("invalid limit: %w", err)
+		}
+	}
+
+	if v := r.URL.Query().Get("since"); v != "" {
+		sinceMs, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, time.Time{}, fmt.Errorf("invalid since: %w", err)
+		}
+		since = time.UnixMilli(sinceMs)
+	}
+
+	return limit, since, nil
+}
+
+// handleScrobble forwards the most recently recorded play to Last.fm on
+// demand, mirroring a Subsonic server's /rest/scrobble.view. It requires
+// SCROBBLE_LASTFM_KEY/SECRET/SESSION to be configured.
+func handleScrobble(w http.ResponseWriter, r *http.Request) {
+	if scrobbler == nil {
+		http.Error(w, "scrobbling is not configured (set SCROBBLE_LASTFM_KEY, SCROBBLE_LASTFM_SECRET, SCROBBLE_LASTFM_SESSION)", http.StatusServiceUnavailable)
+		return
+	}
+
+	entries, err := historyStore.List(r.Context(), 1, time.Time{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(entries) == 0 {
+		http.Error(w, "no history to scrobble", http.StatusNotFound)
+		return
+	}
+
+	if err := scrobbler.Scrobble(r.Context(), entries[0]); err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleStats serves top artists/tracks/albums by play count. Query
+// params: window (a time.ParseDuration string, default "720h" i.e. 30
+// days) and limit (rows per leaderboard, default 10).
+func handleStats(w http.ResponseWriter, r *http.Request) {
+	window := 30 * 24 * time.Hour
+	if v := r.URL.Query().Get("window"); v != "" {
+		var err error
+		window, err = time.ParseDuration(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid window: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit := 10
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid limit: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	stats, err := historyStore.Stats(r.Context(), time.Now().Add(-window), limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+func getCurrentStatus(state *source.NowPlaying) map[string]interface{} {
 	if state == nil {
 		return nil
 	}
@@ -178,107 +443,169 @@ func getCurrentStatus(state *spotify.CurrentlyPlaying) map[string]interface{} {
 
 	// Adjust the progress based on the elapsed time
 	adjustedProgress := state.Progress + int(elapsedTime/time.Millisecond)
-	if adjustedProgress >= state.Item.Duration {
-		adjustedProgress = state.Item.Duration
+	if adjustedProgress >= state.Duration {
+		adjustedProgress = state.Duration
 	}
 
-	// Get the album art URL and extract colors
-	albumArtColors := []color.Color{}
-	albumArtUrl := getAlbumArtURL(state.Item.Album)
-	if albumArtUrl != "" {
-		fmt.Println("Album art URL:", albumArtUrl)
-		albumArtFile, err := downloadAlbumArt(albumArtUrl)
-		if err != nil {
-			fmt.Println("Error downloading album art:", err)
-		}
-		if albumArtFile != "" {
-			albumArtColors = extractColors(albumArtFile)
-			fmt.Println("Colors extracted:", albumArtColors)
+	// The dominant-color palette is extracted once per track change (see
+	// the main polling loop) rather than here, since this function also
+	// runs on every 1-second status push and k-means clustering isn't
+	// cheap enough to redo every tick.
+	swatches := currentSwatches
+
+	// Default background color if no palette could be extracted
+	albumArtColorRGBA := color.RGBA{248, 236, 235, 255}
+	var accentColor, mutedColor *color.RGBA
+	for _, s := range swatches {
+		switch s.Role {
+		case palette.RoleBackground:
+			albumArtColorRGBA = s.RGB
+		case palette.RoleAccent:
+			c := s.RGB
+			accentColor = &c
+		case palette.RoleMuted:
+			if mutedColor == nil {
+				c := s.RGB
+				mutedColor = &c
+			}
 		}
 	}
-
-	// Default color if no colors are extracted
-	albumArtColor := []uint32{248, 236, 235}
-	// Get the first color if any colors are extracted
-	if len(albumArtColors) > 0 {
-		r, g, b, _ := albumArtColors[0].RGBA()
-		albumArtColor = []uint32{r >> 8, g >> 8, b >> 8}
-	}
-	// Convert each uint32 to string
-	albumArtColorStrs := make([]string, len(albumArtColor))
-	for i, num := range albumArtColor {
-		albumArtColorStrs[i] = strconv.Itoa(int(num))
-	}
-	// Join the string slice with commas
-	albumArtColorStr := strings.Join(albumArtColorStrs, ",")
+	albumArtColorStr := rgbaToCSVString(albumArtColorRGBA)
 	fmt.Println("Album art color:", albumArtColorStr)
 
 	// Text color based on album art color
-	textColorRGBA := provideTextColor(color.RGBA{uint8(albumArtColor[0]), uint8(albumArtColor[1]), uint8(albumArtColor[2]), 255})
+	textColorRGBA := provideTextColor(albumArtColorRGBA)
 	fmt.Println("Text color RGBA:", textColorRGBA)
-	textColor := []uint32{uint32(textColorRGBA.R), uint32(textColorRGBA.G), uint32(textColorRGBA.B)}
-	// Convert each uint32 to string
-	textColorStrs := make([]string, len(textColor))
-	for i, num := range textColor {
-		textColorStrs[i] = strconv.Itoa(int(num))
-	}
-	// Join the string slice with commas
-	textColorStr := strings.Join(textColorStrs, ",")
+	textColorStr := rgbaToCSVString(textColorRGBA)
 	fmt.Println("Text color:", textColorStr)
 
-	// Progress color based on album art color
-	progressColorRGBA_Triadic1, progressColorRGBA_Triadic2 := provideTriadicColors(color.RGBA{uint8(albumArtColor[0]), uint8(albumArtColor[1]), uint8(albumArtColor[2]), 255})
-	progressColorRGBA_Analogous1, progressColorRGBA_Analogous2 := provideAnalogousColors(color.RGBA{uint8(albumArtColor[0]), uint8(albumArtColor[1]), uint8(albumArtColor[2]), 255})
-	progressColorRGBA_Complementary := provideComplementaryColor(color.RGBA{uint8(albumArtColor[0]), uint8(albumArtColor[1]), uint8(albumArtColor[2]), 255})
-	progressColorRGBA := chooseBestContrastingColor(
-		color.RGBA{uint8(albumArtColor[0]), uint8(albumArtColor[1]), uint8(albumArtColor[2]), 255},
-		[]color.RGBA{
-			progressColorRGBA_Triadic1,
-			progressColorRGBA_Triadic2,
-			progressColorRGBA_Analogous1,
-			progressColorRGBA_Analogous2,
-			progressColorRGBA_Complementary,
-		})
+	// Progress color: prefer the palette's own accent/muted swatches
+	// (which already contrast with the art itself) before falling back to
+	// the derived color-wheel candidates used when no palette is available.
+	progressCandidates := []color.RGBA{}
+	if accentColor != nil {
+		progressCandidates = append(progressCandidates, *accentColor)
+	}
+	if mutedColor != nil {
+		progressCandidates = append(progressCandidates, *mutedColor)
+	}
+	triadic1, triadic2 := provideTriadicColors(albumArtColorRGBA)
+	analogous1, analogous2 := provideAnalogousColors(albumArtColorRGBA)
+	complementary := provideComplementaryColor(albumArtColorRGBA)
+	progressCandidates = append(progressCandidates, triadic1, triadic2, analogous1, analogous2, complementary)
+
+	progressColorRGBA := chooseBestContrastingColor(albumArtColorRGBA, progressCandidates)
 	fmt.Println("Progress color RGBA:", progressColorRGBA)
-	progressColor := []uint32{uint32(progressColorRGBA.R), uint32(progressColorRGBA.G), uint32(progressColorRGBA.B)}
-	// Convert each uint32 to string
-	progressColorStrs := make([]string, len(progressColor))
-	for i, num := range progressColor {
-		progressColorStrs[i] = strconv.Itoa(int(num))
-	}
-	// Join the string slice with commas
-	progressColorStr := strings.Join(progressColorStrs, ",")
+	progressColorStr := rgbaToCSVString(progressColorRGBA)
 	fmt.Println("Progress color:", progressColorStr)
 
+	paletteReport := make([]map[string]interface{}, len(swatches))
+	for i, s := range swatches {
+		paletteReport[i] = map[string]interface{}{
+			"rgb":            []uint32{uint32(s.RGB.R), uint32(s.RGB.G), uint32(s.RGB.B)},
+			"population_pct": s.PopulationPct,
+			"role":           string(s.Role),
+		}
+	}
+
+	// Supplementary track/album/artist info is fetched once per track
+	// change (see the main polling loop) rather than here, since this
+	// function also runs on every 1-second status push.
+	trackMetadata := currentMetadata
+
+	// Work out the active and upcoming lyric line, if synced lyrics are
+	// available for the current track.
+	var lyricsCurrent, lyricsNext string
+	var lyricsLines []lyrics.LyricLine
+	if currentLyrics != nil {
+		lyricsLines = currentLyrics.Lines
+		currentIdx, nextIdx := currentLyrics.CurrentLine(adjustedProgress)
+		if currentIdx >= 0 {
+			lyricsCurrent = currentLyrics.Lines[currentIdx].Text
+		}
+		if nextIdx >= 0 {
+			lyricsNext = currentLyrics.Lines[nextIdx].Text
+		}
+	}
+
 	// Create a report object with adjusted progress
+	// Some sources (e.g. an MPD stream with no duration tag) report a
+	// zero track length; guard the percentage against dividing by zero
+	// rather than emitting NaN, which json.Marshal rejects outright.
+	var progressPct float64
+	if state.Duration > 0 {
+		progressPct = float64(adjustedProgress) / float64(state.Duration) * 100
+	}
+
 	report := map[string]interface{}{
 		"timestamp":           time.Now().Unix(),
 		"playback_state":      state.Playing,
-		"track":               state.Item.Name,
-		"album":               state.Item.Album.Name,
-		"artist":              state.Item.Artists[0].Name,
-		"endpoint":            state.PlaybackContext.Endpoint,
-		"progress_pct":        float64(adjustedProgress) / float64(state.Item.Duration) * 100,
-		"progress_pct_str":    fmt.Sprintf("%.2f%%", float64(adjustedProgress)/float64(state.Item.Duration)*100),
+		"track":               state.Track,
+		"album":               state.Album,
+		"artist":              state.Artist,
+		"endpoint":            state.Endpoint,
+		"progress_pct":        progressPct,
+		"progress_pct_str":    fmt.Sprintf("%.2f%%", progressPct),
 		"progress_ms":         adjustedProgress,
-		"duration_ms":         state.Item.Duration,
-		"remaining_ms":        state.Item.Duration - adjustedProgress,
+		"duration_ms":         state.Duration,
+		"remaining_ms":        state.Duration - adjustedProgress,
 		"progress_str":        fmt.Sprintf("%d:%02d", adjustedProgress/60000, (adjustedProgress/1000)%60),
-		"duration_str":        fmt.Sprintf("%d:%02d", state.Item.Duration/60000, (state.Item.Duration/1000)%60),
-		"remaining_str":       fmt.Sprintf("%d:%02d", (state.Item.Duration-adjustedProgress)/60000, ((state.Item.Duration-adjustedProgress)/1000)%60),
-		"album_art_url":       getAlbumArtURL(state.Item.Album),
+		"duration_str":        fmt.Sprintf("%d:%02d", state.Duration/60000, (state.Duration/1000)%60),
+		"remaining_str":       fmt.Sprintf("%d:%02d", (state.Duration-adjustedProgress)/60000, ((state.Duration-adjustedProgress)/1000)%60),
+		"album_art_url":       state.AlbumArtURL,
 		"album_art_color_rgb": albumArtColorStr,
-		"album_art_colors":    albumArtColors,
+		"palette":             paletteReport,
 		"text_color_rgb":      textColorStr,
-		"text_color":          textColor,
+		"text_color":          []uint32{uint32(textColorRGBA.R), uint32(textColorRGBA.G), uint32(textColorRGBA.B)},
 		"progress_color_rgb":  progressColorStr,
-		"progress_color":      progressColor,
+		"progress_color":      []uint32{uint32(progressColorRGBA.R), uint32(progressColorRGBA.G), uint32(progressColorRGBA.B)},
+		"track_mbid":          trackMetadata.TrackMBID,
+		"album_mbid":          trackMetadata.AlbumMBID,
+		"artist_mbid":         trackMetadata.ArtistMBID,
+		"artist_bio":          trackMetadata.ArtistBio,
+		"album_description":   trackMetadata.AlbumDescription,
+		"similar_artists":     trackMetadata.SimilarArtists,
+		"tags":                trackMetadata.Tags,
+		"wiki_summary":        trackMetadata.WikiSummary,
+		"lyrics_current":      lyricsCurrent,
+		"lyrics_next":         lyricsNext,
+		"lyrics_lines":        lyricsLines,
 	}
 
 	return report
 }
 
-func sendCurrentStatus(state *spotify.CurrentlyPlaying) {
+// recordTrackHistory persists the just-finished play of prev to history,
+// and, once it crosses Last.fm's scrobble threshold, forwards it to
+// Last.fm if a scrobbler is configured. prev.Progress is however far the
+// main polling loop had observed it getting before the track changed, so
+// this is an approximation bounded by the 5-second poll interval.
+func recordTrackHistory(prev *source.NowPlaying) {
+	entry := history.Entry{
+		Timestamp:  time.UnixMilli(prev.Timestamp),
+		Track:      prev.Track,
+		Album:      prev.Album,
+		Artist:     prev.Artist,
+		TrackID:    prev.TrackID,
+		DurationMs: prev.Duration,
+		PlayedMs:   prev.Progress,
+		Scrobbled:  history.Scrobbled(prev.Duration, prev.Progress),
+	}
+
+	if _, err := historyStore.Record(context.Background(), entry); err != nil {
+		fmt.Println("Error recording track history:", err)
+	}
+
+	if entry.Scrobbled && scrobbler != nil {
+		go func(e history.Entry) {
+			if err := scrobbler.Scrobble(context.Background(), e); err != nil {
+				fmt.Println("Error scrobbling to Last.fm:", err)
+			}
+		}(entry)
+	}
+}
+
+func sendCurrentStatus(state *source.NowPlaying) {
 	if state == nil {
 		return
 	}
@@ -317,13 +644,6 @@ func sendCurrentStatus(state *spotify.CurrentlyPlaying) {
 	}
 }
 
-func getAlbumArtURL(album spotify.SimpleAlbum) string {
-	if len(album.Images) > 0 {
-		return album.Images[0].URL
-	}
-	return ""
-}
-
 func downloadAlbumArt(url string) (filename string, err error) {
 	// Check if the URL is empty
 	if url == "" {
@@ -386,71 +706,163 @@ func downloadAlbumArt(url string) (filename string, err error) {
 	return
 }
 
-func extractColors(imagePath string) []color.Color {
-	imageFile, _ := os.Open(imagePath)
+func extractPalette(imagePath string, k int) []palette.Swatch {
+	imageFile, err := os.Open(imagePath)
+	if err != nil {
+		fmt.Println("Error opening album art:", err)
+		return nil
+	}
 	defer imageFile.Close()
 
-	image, format, err := image.Decode(imageFile)
+	img, format, err := image.Decode(imageFile)
 	if err != nil {
 		fmt.Println("Error decoding image:", err)
 		return nil
 	}
 	fmt.Println("Image format:", format)
-	colors := color_extractor.ExtractColors(image)
 
-	return colors
+	return palette.Extract(img, k)
 }
 
-func convertToXYZ(c color.Color) (x, y, z float64) {
-	// First convert c to colorful.Color
-	cfColor, ok := colorful.MakeColor(c)
-	if !ok {
-		// Handle error case where conversion failed
-		return
+// rgbaToCSVString renders a color as the "r,g,b" string the player
+// template and WebSocket payload embed into CSS rgba(...) calls.
+func rgbaToCSVString(c color.RGBA) string {
+	return strings.Join([]string{
+		strconv.Itoa(int(c.R)),
+		strconv.Itoa(int(c.G)),
+		strconv.Itoa(int(c.B)),
+	}, ",")
+}
+
+// srgbChannelToLinear converts a single sRGB channel value in [0,1] to its
+// linear-light equivalent, per the WCAG 2.1 relative luminance formula.
+func srgbChannelToLinear(c float64) float64 {
+	if c <= 0.03928 {
+		return c / 12.92
 	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
 
-	// Then use cfColor.Xyz() to get XYZ values
-	x, y, z = cfColor.Xyz()
-	return
+// relativeLuminance computes the WCAG 2.1 relative luminance of a color:
+// https://www.w3.org/TR/WCAG21/#dfn-relative-luminance
+func relativeLuminance(c color.Color) float64 {
+	r, g, b, _ := c.RGBA()
+	rLin := srgbChannelToLinear(float64(r>>8) / 255)
+	gLin := srgbChannelToLinear(float64(g>>8) / 255)
+	bLin := srgbChannelToLinear(float64(b>>8) / 255)
+	return 0.2126*rLin + 0.7152*gLin + 0.0722*bLin
 }
 
+// calculateContrastRatio computes the WCAG 2.1 contrast ratio between two
+// colors, a value from 1 (no contrast) to 21 (black on white).
 func calculateContrastRatio(c1, c2 color.Color) float64 {
-	// Convert colors to XYZ color space
-	x1, y1, z1 := convertToXYZ(c1)
-	x2, y2, z2 := convertToXYZ(c2)
+	lum1 := relativeLuminance(c1)
+	lum2 := relativeLuminance(c2)
+
+	lighter, darker := lum1, lum2
+	if lum2 > lum1 {
+		lighter, darker = lum2, lum1
+	}
+
+	return (lighter + 0.05) / (darker + 0.05)
+}
+
+// apcaY is the APCA pre-exponent luminance: sRGB converted straight to
+// linear light via a simple gamma 2.4 (no WCAG-style piecewise toe).
+func apcaY(c color.Color) float64 {
+	r, g, b, _ := c.RGBA()
+	rLin := math.Pow(float64(r>>8)/255, 2.4)
+	gLin := math.Pow(float64(g>>8)/255, 2.4)
+	bLin := math.Pow(float64(b>>8)/255, 2.4)
+	return 0.2126729*rLin + 0.7151522*gLin + 0.0721750*bLin
+}
+
+// apcaContrast computes the APCA (Accessible Perceptual Contrast
+// Algorithm) Lc value between text and background colors, per the
+// APCA-W3 0.1.9 "simple" reference formula:
+// https://github.com/Myndex/apca-w3
+func apcaContrast(text, bg color.Color) float64 {
+	const (
+		blackClampLimit = 0.022
+		blackClampExp   = 1.414
+		scale           = 1.14
+		loClip          = 0.1
+		loOffset        = 0.027
+	)
+
+	yTxt := apcaY(text)
+	yBg := apcaY(bg)
+
+	if yTxt < blackClampLimit {
+		yTxt += math.Pow(blackClampLimit-yTxt, blackClampExp)
+	}
+	if yBg < blackClampLimit {
+		yBg += math.Pow(blackClampLimit-yBg, blackClampExp)
+	}
+
+	var signedContrast float64
+	if yBg > yTxt {
+		// Normal polarity: dark text on a light background.
+		signedContrast = (math.Pow(yBg, 0.56) - math.Pow(yTxt, 0.57)) * scale
+	} else {
+		// Reverse polarity: light text on a dark background.
+		signedContrast = (math.Pow(yBg, 0.65) - math.Pow(yTxt, 0.62)) * scale
+	}
 
-	// Calculate relative luminance
-	lum1 := 0.2126*x1 + 0.7152*y1 + 0.0722*z1
-	lum2 := 0.2126*x2 + 0.7152*y2 + 0.0722*z2
+	if math.Abs(signedContrast) < loClip {
+		return 0
+	}
+	if signedContrast > 0 {
+		return (signedContrast - loOffset) * 100
+	}
+	return (signedContrast + loOffset) * 100
+}
+
+// Contrast grading thresholds for "passes AA" under each mode. 4.5 is the
+// WCAG 2.1 AA threshold for normal text; 60 is APCA's commonly cited rough
+// equivalent for body text (Lc values run roughly 0-106).
+const (
+	wcagAAThreshold = 4.5
+	apcaAAThreshold = 60
+)
+
+// contrastScore rates the contrast between two colors using whichever
+// algorithm CONTRAST_MODE selects, always as a positive "bigger is better"
+// number.
+func contrastScore(c1, c2 color.Color) float64 {
+	if contrastMode == "apca" {
+		return math.Abs(apcaContrast(c1, c2))
+	}
+	return calculateContrastRatio(c1, c2)
+}
 
-	// Calculate contrast ratio
-	if lum1 > lum2 {
-		return (lum1 + 0.05) / (lum2 + 0.05)
+func passesAA(score float64) bool {
+	if contrastMode == "apca" {
+		return score >= apcaAAThreshold
 	}
-	return (lum2 + 0.05) / (lum1 + 0.05)
+	return score >= wcagAAThreshold
 }
 
 func provideTextColor(bgColor color.RGBA) color.RGBA {
 	return chooseBestContrastingColor(bgColor, []color.RGBA{{0, 0, 0, 255}, {255, 255, 255, 255}})
 }
 
+// chooseBestContrastingColor returns the first candidate that passes AA
+// against c, so colors are accessibility-graded rather than just picking
+// whichever scores highest. If no candidate passes AA, it falls back to
+// the one with the highest contrast score.
 func chooseBestContrastingColor(c color.RGBA, colors []color.RGBA) color.RGBA {
 	var bestColor color.RGBA
-	var bestContrast float64
-
-	// Convert base color to colorful.Color
-	baseColor := colorful.Color{R: float64(c.R) / 255, G: float64(c.G) / 255, B: float64(c.B) / 255}
+	var bestScore float64
 
 	for _, col := range colors {
-		// Convert color to colorful.Color
-		contrastColor := colorful.Color{R: float64(col.R) / 255, G: float64(col.G) / 255, B: float64(col.B) / 255}
-
-		// Calculate contrast ratio
-		contrast := calculateContrastRatio(baseColor, contrastColor)
+		score := contrastScore(c, col)
+		if passesAA(score) {
+			return col
+		}
 
-		// Update best color if contrast is higher
-		if contrast > bestContrast {
-			bestContrast = contrast
+		if score > bestScore {
+			bestScore = score
 			bestColor = col
 		}
 	}
@@ -596,6 +1008,16 @@ const playerTemplate string = `
 				justify-content: space-between;
 				color: rgba({{.TextColorRGB}}, 1.0);
 			}
+
+			.lyric-line {
+				font-size: 13px;
+				font-style: italic;
+				margin: 6px 0 0;
+				color: rgba({{.TextColorRGB}}, 0.85);
+				overflow: hidden;
+				white-space: nowrap;
+				text-overflow: ellipsis;
+			}
 		</style>
 	</head>
 	<body>
@@ -613,6 +1035,7 @@ const playerTemplate string = `
 					<span class="current-time">{{.ProgressStr}}</span>
 					<span class="total-time">{{.DurationStr}}</span>
 				</div>
+				<p class="lyric-line">{{.LyricsCurrent}}</p>
 			</div>
 		</div>
 		<script>
@@ -639,6 +1062,7 @@ const playerTemplate string = `
 					document.querySelector(".artist").style.color = "rgba(" + data.text_color_rgb + ", 1.0)";
 					document.querySelector(".duration").style.color = "rgba(" + data.text_color_rgb + ", 1.0)";
 					document.querySelector(".progress").style.backgroundColor = "rgba(" + data.progress_color_rgb + ", 1.0)";
+					document.querySelector(".lyric-line").textContent = data.lyrics_current || "";
 				};
 
 				socket.onclose = function (event) {